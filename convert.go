@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// jsonSheet is the shape accepted for a single sheet on the JSON->xlsx
+// path: the "rows" from the Sheet type, plus an optional explicit
+// "headers" field for callers (e.g. editors round-tripping corrections)
+// that want to pin column order instead of relying on inference.
+type jsonSheet struct {
+	Name    string                   `json:"name"`
+	Headers []string                 `json:"headers"`
+	Rows    []map[string]interface{} `json:"rows"`
+}
+
+type jsonDocument struct {
+	Sheets []jsonSheet `json:"sheets"`
+}
+
+// runConvert handles `palestine-pulse convert --to <json|xlsx> input output`,
+// the single-file inverse of the directory-scanning default command.
+func runConvert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	to := fs.String("to", "json", "output format: json or xlsx")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	rest := fs.Args()
+	if len(rest) != 2 {
+		return fmt.Errorf("usage: palestine-pulse convert --to <json|xlsx> <input> <output>")
+	}
+	input, output := rest[0], rest[1]
+
+	switch *to {
+	case "xlsx":
+		return convertJSONToXLSX(input, output)
+	case "json":
+		return convertXLSXToJSON(input, output)
+	default:
+		return fmt.Errorf("unsupported --to %q (want json or xlsx)", *to)
+	}
+}
+
+// convertXLSXToJSON is the single-file counterpart to Converter.Run, for
+// callers who want to name an exact input and output path.
+func convertXLSXToJSON(input, output string) error {
+	f, err := excelize.OpenFile(input)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", input, err)
+	}
+
+	schema, err := loadSchema(input, ".xlsx")
+	if err != nil {
+		return fmt.Errorf("loading schema: %w", err)
+	}
+
+	sheets, err := ImportXLSX(f, schema)
+	if err != nil {
+		return fmt.Errorf("reading sheets: %w", err)
+	}
+
+	jsonData, err := json.MarshalIndent(Document{Sheets: sheets}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling to JSON: %w", err)
+	}
+
+	return os.WriteFile(output, jsonData, 0644)
+}
+
+// convertJSONToXLSX writes an xlsx workbook from JSON previously produced
+// by this tool: either the nested multi-sheet document, a single
+// {"headers", "rows"} object, or a bare array of row objects. Editors and
+// data partners round-trip corrections through this path.
+func convertJSONToXLSX(input, output string) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", input, err)
+	}
+
+	sheets, err := parseJSONSheets(data)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", input, err)
+	}
+	if len(sheets) == 0 {
+		return fmt.Errorf("%s has no rows to export", input)
+	}
+
+	f := excelize.NewFile()
+
+	for i, sheet := range sheets {
+		name := sheet.Name
+		if name == "" {
+			name = fmt.Sprintf("Sheet%d", i+1)
+		}
+
+		if i == 0 {
+			f.SetSheetName(f.GetSheetName(0), name)
+		} else if _, err := f.NewSheet(name); err != nil {
+			return fmt.Errorf("creating sheet %q: %w", name, err)
+		}
+
+		headers := sheet.Headers
+		if len(headers) == 0 {
+			headers = collectHeaders(sheet.Rows)
+		}
+
+		headerRow := make([]interface{}, len(headers))
+		for i, h := range headers {
+			headerRow[i] = h
+		}
+		if err := f.SetSheetRow(name, "A1", &headerRow); err != nil {
+			return fmt.Errorf("writing headers for sheet %q: %w", name, err)
+		}
+
+		for rowIdx, row := range sheet.Rows {
+			axis, err := excelize.CoordinatesToCellName(1, rowIdx+2)
+			if err != nil {
+				return err
+			}
+
+			values := make([]interface{}, len(headers))
+			for colIdx, header := range headers {
+				values[colIdx] = row[header]
+			}
+			if err := f.SetSheetRow(name, axis, &values); err != nil {
+				return fmt.Errorf("writing row %d of sheet %q: %w", rowIdx, name, err)
+			}
+		}
+	}
+
+	return f.SaveAs(output)
+}
+
+// parseJSONSheets accepts the nested multi-sheet document, a single
+// {"headers", "rows"} object, or a bare array of row objects, in that
+// order of preference.
+func parseJSONSheets(data []byte) ([]jsonSheet, error) {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err == nil && len(doc.Sheets) > 0 {
+		return doc.Sheets, nil
+	}
+
+	var single jsonSheet
+	if err := json.Unmarshal(data, &single); err == nil && len(single.Rows) > 0 {
+		return []jsonSheet{single}, nil
+	}
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("unrecognized JSON shape: %w", err)
+	}
+
+	return []jsonSheet{{Rows: rows}}, nil
+}
+
+// collectHeaders builds a stable column order from the union of row keys
+// when no explicit "headers" field was given.
+func collectHeaders(rows []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var headers []string
+
+	for _, row := range rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				headers = append(headers, key)
+			}
+		}
+	}
+
+	sort.Strings(headers)
+	return headers
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func writeTestFile(path, content string) error {
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func mustUnmarshalFile(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("unmarshalling %s: %v", path, err)
+	}
+}
+
+func TestParseJSONSheets(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []jsonSheet
+		wantErr bool
+	}{
+		{
+			name:  "nested multi-sheet document",
+			input: `{"sheets":[{"name":"People","headers":["name","age"],"rows":[{"name":"A","age":30}]}]}`,
+			want: []jsonSheet{
+				{Name: "People", Headers: []string{"name", "age"}, Rows: []map[string]interface{}{{"name": "A", "age": float64(30)}}},
+			},
+		},
+		{
+			name:  "single sheet object with headers",
+			input: `{"headers":["a","b"],"rows":[{"a":1,"b":2}]}`,
+			want: []jsonSheet{
+				{Headers: []string{"a", "b"}, Rows: []map[string]interface{}{{"a": float64(1), "b": float64(2)}}},
+			},
+		},
+		{
+			name:  "bare array of row objects",
+			input: `[{"a":1},{"a":2}]`,
+			want: []jsonSheet{
+				{Rows: []map[string]interface{}{{"a": float64(1)}, {"a": float64(2)}}},
+			},
+		},
+		{
+			name:    "unrecognized shape",
+			input:   `"just a string"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJSONSheets([]byte(tt.input))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseJSONSheets: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseJSONSheets = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectHeaders(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"b": 1, "a": 2},
+		{"c": 3},
+	}
+
+	got := collectHeaders(rows)
+	want := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("collectHeaders = %v, want %v (stable sorted order)", got, want)
+	}
+}
+
+func TestJSONToXLSXToJSONRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "in.json")
+	xlsxPath := filepath.Join(dir, "out.xlsx")
+	roundTripPath := filepath.Join(dir, "roundtrip.json")
+
+	input := `{"sheets":[{"name":"People","headers":["name","age"],"rows":[{"name":"Alice","age":30},{"name":"Bob","age":25}]}]}`
+	if err := writeTestFile(jsonPath, input); err != nil {
+		t.Fatalf("writing input JSON: %v", err)
+	}
+
+	if err := convertJSONToXLSX(jsonPath, xlsxPath); err != nil {
+		t.Fatalf("convertJSONToXLSX: %v", err)
+	}
+	if err := convertXLSXToJSON(xlsxPath, roundTripPath); err != nil {
+		t.Fatalf("convertXLSXToJSON: %v", err)
+	}
+
+	var doc Document
+	mustUnmarshalFile(t, roundTripPath, &doc)
+
+	if len(doc.Sheets) != 1 || doc.Sheets[0].Name != "People" {
+		t.Fatalf("got %+v, want a single sheet named People", doc)
+	}
+
+	names := rowValues(doc.Sheets[0].Rows, "name")
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"Alice", "Bob"}) {
+		t.Errorf("round-tripped names = %v, want [Alice Bob]", names)
+	}
+}
+
+func rowValues(rows []map[string]interface{}, key string) []string {
+	var values []string
+	for _, row := range rows {
+		if v, ok := row[key].(string); ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Encoder serializes a sheet's rows to w in a particular output format.
+type Encoder interface {
+	Encode(w io.Writer, rows []map[string]interface{}) error
+}
+
+// encoders are the --format implementations, keyed by flag value.
+var encoders = map[string]Encoder{
+	"json":    jsonEncoder{},
+	"ndjson":  ndjsonEncoder{},
+	"csv":     csvEncoder{},
+	"json.gz": gzipEncoder{Encoder: jsonEncoder{}},
+}
+
+// formatExt is the file extension written for each --format value.
+var formatExt = map[string]string{
+	"json":    ".json",
+	"ndjson":  ".ndjson",
+	"csv":     ".csv",
+	"json.gz": ".json.gz",
+}
+
+// jsonEncoder writes rows as a single pretty-printed JSON array, the
+// tool's original, backward-compatible output shape.
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(w io.Writer, rows []map[string]interface{}) error {
+	data, err := json.MarshalIndent(rows, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ndjsonEncoder writes one JSON object per line, so downstream tools
+// (DuckDB, BigQuery) can stream rows without holding the whole array in
+// memory.
+type ndjsonEncoder struct{}
+
+func (ndjsonEncoder) Encode(w io.Writer, rows []map[string]interface{}) error {
+	enc := json.NewEncoder(w)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvEncoder re-emits rows with the header row in a stable (sorted)
+// column order, since a CSV has no per-cell key to fall back on.
+type csvEncoder struct{}
+
+func (csvEncoder) Encode(w io.Writer, rows []map[string]interface{}) error {
+	headers := collectHeaders(rows)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(headers); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(headers))
+		for i, header := range headers {
+			if v, ok := row[header]; ok && v != nil {
+				record[i] = fmt.Sprintf("%v", v)
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// gzipEncoder wraps another Encoder's output in a gzip stream.
+type gzipEncoder struct {
+	Encoder Encoder
+}
+
+func (g gzipEncoder) Encode(w io.Writer, rows []map[string]interface{}) error {
+	gz := gzip.NewWriter(w)
+	if err := g.Encoder.Encode(gz, rows); err != nil {
+		gz.Close()
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+var encoderTestRows = []map[string]interface{}{
+	{"name": "Alice", "age": 30},
+	{"name": "Bob", "age": 25},
+}
+
+func TestJSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (jsonEncoder{}).Encode(&buf, encoderTestRows); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshalling output: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d rows, want 2", len(got))
+	}
+}
+
+func TestNDJSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (ndjsonEncoder{}).Encode(&buf, encoderTestRows); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	lines := bytes.Split(bytes.TrimRight(buf.Bytes(), "\n"), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one JSON object per row (2)", len(lines))
+	}
+	for _, line := range lines {
+		var row map[string]interface{}
+		if err := json.Unmarshal(line, &row); err != nil {
+			t.Errorf("line %q is not a single JSON object: %v", line, err)
+		}
+	}
+}
+
+func TestCSVEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (csvEncoder{}).Encode(&buf, encoderTestRows); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	want := "age,name\n30,Alice\n25,Bob\n"
+	if buf.String() != want {
+		t.Errorf("csv output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCSVEncoderMissingValueIsBlank(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"a": 1, "b": 2},
+		{"a": 3},
+	}
+
+	var buf bytes.Buffer
+	if err := (csvEncoder{}).Encode(&buf, rows); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	want := "a,b\n1,2\n3,\n"
+	if buf.String() != want {
+		t.Errorf("csv output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestGzipEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := gzipEncoder{Encoder: jsonEncoder{}}
+	if err := enc.Encode(&buf, encoderTestRows); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	gr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer gr.Close()
+
+	decompressed, err := io.ReadAll(gr)
+	if err != nil {
+		t.Fatalf("reading decompressed data: %v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(decompressed, &got); err != nil {
+		t.Fatalf("unmarshalling decompressed output: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d rows, want 2", len(got))
+	}
+}
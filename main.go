@@ -1,300 +1,301 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/xuri/excelize/v2"
 )
 
-func main() {
-	dir := "src/data"
-
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		fmt.Println("Error reading directory:", err)
-		return
-	}
+// Sheet holds the rows extracted from a single worksheet, keyed by header.
+type Sheet struct {
+	Name string                   `json:"name"`
+	Rows []map[string]interface{} `json:"rows"`
+}
 
-	for _, file := range files {
-		ext := filepath.Ext(file.Name())
-		if ext == ".xlsx" || ext == ".xls" {
-			filePath := filepath.Join(dir, file.Name())
-			jsonPath := strings.TrimSuffix(filePath, ext) + ".json"
-
-			f, err := excelize.OpenFile(filePath)
-			if err != nil {
-				fmt.Println("Error opening file:", err)
-				continue
-			}
+// Document is the nested shape written for a workbook when --split is not set.
+type Document struct {
+	Sheets []Sheet `json:"sheets"`
+}
 
-			sheetName := f.GetSheetName(0)
-			rows, err := f.GetRows(sheetName)
-			if err != nil {
-				fmt.Println("Error getting rows:", err)
-				continue
-			}
+// Converter watches or walks a directory of xlsx/xls workbooks, converting
+// each one with a bounded pool of worker goroutines. Format selects the
+// Encoder (see encoder.go) used for the output files; it defaults to
+// "json" for backward compatibility.
+type Converter struct {
+	Dir     string
+	Split   bool
+	Format  string
+	Workers int
+}
 
-			if len(rows) == 0 {
-				fmt.Println("No rows found in", file.Name())
-				continue
-			}
+// NewConverter builds a Converter with a worker pool sized to the host.
+func NewConverter(dir string, split bool, format string) *Converter {
+	return &Converter{Dir: dir, Split: split, Format: format, Workers: runtime.NumCPU()}
+}
 
-			headers := rows[0]
-			data := make([]map[string]interface{}, 0)
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "convert" {
+		if err := runConvert(os.Args[2:]); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-			for _, row := range rows[1:] {
-				rowData := make(map[string]interface{})
-				for i, cell := range row {
-					if i < len(headers) {
-						rowData[headers[i]] = cell
-					}
-				}
-				data = append(data, rowData)
-			}
+	split := flag.Bool("split", false, "write one JSON file per sheet instead of a single nested document")
+	watch := flag.Bool("watch", false, "watch src/data for changes and reconvert affected workbooks")
+	format := flag.String("format", "json", "output encoding: json, ndjson, csv, or json.gz")
+	flag.Parse()
 
-			jsonData, err := json.MarshalIndent(data, "", "  ")
-			if err != nil {
-				fmt.Println("Error marshalling to JSON:", err)
-				continue
-			}
+	if _, ok := encoders[*format]; !ok {
+		fmt.Printf("Error: unsupported --format %q (want json, ndjson, csv, or json.gz)\n", *format)
+		os.Exit(1)
+	}
 
-			err = ioutil.WriteFile(jsonPath, jsonData, 0644)
-			if err != nil {
-				fmt.Println("Error writing JSON file:", err)
-				continue
-			}
+	c := NewConverter("src/data", *split, *format)
 
-			fmt.Println("Converted", file.Name(), "to", filepath.Base(jsonPath))
+	if *watch {
+		if err := c.Watch(); err != nil {
+			fmt.Println("Error watching directory:", err)
 		}
+		return
 	}
-}
 
+	if err := c.Run(); err != nil {
+		fmt.Println("Error reading directory:", err)
+	}
+}
 
-import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
-	"strings"
+// Run discovers every workbook in c.Dir and converts them concurrently
+// across c.Workers goroutines, then waits for all of them to finish.
+func (c *Converter) Run() error {
+	files, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return err
+	}
 
-	"github.com/xuri/excelize/v2"
-)
+	jobs := make(chan string)
+	var wg sync.WaitGroup
 
-func main() {
-	dir := "src/data"
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
 
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		fmt.Println("Error reading directory:", err)
-		return
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filePath := range jobs {
+				c.convertFile(filePath)
+			}
+		}()
 	}
 
 	for _, file := range files {
 		ext := filepath.Ext(file.Name())
-		if ext == ".xlsx" || ext == ".xls" {
-			filePath := filepath.Join(dir, file.Name())
-			jsonPath := strings.TrimSuffix(filePath, ext) + ".json"
-
-			f, err := excelize.OpenFile(filePath)
-			if err != nil {
-				fmt.Println("Error opening file:", err)
-				continue
-			}
+		if ext != ".xlsx" && ext != ".xls" {
+			continue
+		}
+		jobs <- filepath.Join(c.Dir, file.Name())
+	}
+	close(jobs)
 
-			sheetName := f.GetSheetName(0)
-			rows, err := f.GetRows(sheetName)
-			if err != nil {
-				fmt.Println("Error getting rows:", err)
-				continue
-			}
+	wg.Wait()
+	return nil
+}
 
-			if len(rows) == 0 {
-				fmt.Println("No rows found in", file.Name())
-				continue
-			}
+// convertFile reads one workbook and writes its JSON output, logging
+// failures rather than returning them so one bad file doesn't stop the
+// pool.
+func (c *Converter) convertFile(filePath string) {
+	ext := filepath.Ext(filePath)
 
-			headers := rows[0]
-			data := make([]map[string]interface{}, 0)
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		return
+	}
 
-			for _, row := range rows[1:] {
-				rowData := make(map[string]interface{})
-				for i, cell := range row {
-					if i < len(headers) {
-						rowData[headers[i]] = cell
-					}
-				}
-				data = append(data, rowData)
-			}
+	schema, err := loadSchema(filePath, ext)
+	if err != nil {
+		fmt.Println("Error loading schema.yaml:", err)
+		return
+	}
 
-			jsonData, err := json.MarshalIndent(data, "", "  ")
-			if err != nil {
-				fmt.Println("Error marshalling to JSON:", err)
-				continue
-			}
+	sheets, err := ImportXLSX(f, schema)
+	if err != nil {
+		fmt.Println("Error reading sheets:", err)
+		return
+	}
 
-			err = ioutil.WriteFile(jsonPath, jsonData, 0644)
-			if err != nil {
-				fmt.Println("Error writing JSON file:", err)
-				continue
-			}
+	if len(sheets) == 0 {
+		fmt.Println("No sheets found in", filepath.Base(filePath))
+		return
+	}
 
-			fmt.Println("Converted", file.Name(), "to", filepath.Base(jsonPath))
+	if c.Split {
+		if err := writeSplit(filePath, ext, sheets, c.Format); err != nil {
+			fmt.Println("Error writing output files:", err)
 		}
+		return
 	}
-}
 
-
-import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
-	"strings"
-
-	"github.com/xuri/excelize/v2"
-)
-
-func main() {
-	dir := "src/data"
-
-	files, err := ioutil.ReadDir(dir)
+	wrote, err := writeDocument(filePath, ext, sheets, c.Format)
 	if err != nil {
-		fmt.Println("Error reading directory:", err)
+		fmt.Println("Error writing output file:", err)
 		return
 	}
 
-	for _, file := range files {
-		ext := filepath.Ext(file.Name())
-		if ext == ".xlsx" || ext == ".xls" {
-			filePath := filepath.Join(dir, file.Name())
-			jsonPath := strings.TrimSuffix(filePath, ext) + ".json"
-
-			f, err := excelize.OpenFile(filePath)
-			if err != nil {
-				fmt.Println("Error opening file:", err)
-				continue
-			}
+	outName := filepath.Base(strings.TrimSuffix(filePath, ext) + formatExt[c.Format])
+	if wrote {
+		fmt.Println("Converted", filepath.Base(filePath), "to", outName)
+	} else {
+		fmt.Println(outName, "is already up to date")
+	}
+}
 
-			sheetName := f.GetSheetName(0)
-			rows, err := f.GetRows(sheetName)
-			if err != nil {
-				fmt.Println("Error getting rows:", err)
-				continue
-			}
+// ImportXLSX reads every sheet in f, in workbook order, turning each row
+// into a map keyed by its column header. Column values are coerced to the
+// type inferred by inferColumnTypes (overridden by schema, when set).
+func ImportXLSX(f *excelize.File, schema ColumnSchema) ([]Sheet, error) {
+	var sheets []Sheet
 
-			if len(rows) == 0 {
-				fmt.Println("No rows found in", file.Name())
-				continue
-			}
+	for _, name := range f.GetSheetList() {
+		rows, err := f.GetRows(name)
+		if err != nil {
+			return nil, fmt.Errorf("reading sheet %q: %w", name, err)
+		}
 
-			headers := rows[0]
-			data := make([]map[string]interface{}, 0)
+		if len(rows) == 0 {
+			continue
+		}
+
+		headers := rows[0]
+		columnTypes := inferColumnTypes(f, name, headers, rows[1:], schema)
+		data := make([]map[string]interface{}, 0, len(rows)-1)
 
-			for _, row := range rows[1:] {
-				rowData := make(map[string]interface{})
-				for i, cell := range row {
-					if i < len(headers) {
-						rowData[headers[i]] = cell
-					}
+		for rowIdx, row := range rows[1:] {
+			rowData := make(map[string]interface{})
+			for i, header := range headers {
+				if i >= len(row) || row[i] == "" {
+					rowData[header] = nil
+					continue
 				}
-				data = append(data, rowData)
+				rowData[header] = coerceCell(f, name, columnTypes[header], row[i], rowIdx, i)
 			}
+			data = append(data, rowData)
+		}
 
-			jsonData, err := json.MarshalIndent(data, "", "  ")
-			if err != nil {
-				fmt.Println("Error marshalling to JSON:", err)
-				continue
-			}
+		sheets = append(sheets, Sheet{Name: name, Rows: data})
+	}
 
-			err = ioutil.WriteFile(jsonPath, jsonData, 0644)
-			if err != nil {
-				fmt.Println("Error writing JSON file:", err)
-				continue
-			}
+	return sheets, nil
+}
+
+// writeDocument writes the nested multi-sheet shape, which only makes
+// sense as JSON (optionally gzipped) — ndjson and csv are flat, per-sheet
+// formats, so they require --split.
+func writeDocument(filePath, ext string, sheets []Sheet, format string) (bool, error) {
+	jsonPath := strings.TrimSuffix(filePath, ext) + formatExt[format]
 
-			fmt.Println("Converted", file.Name(), "to", filepath.Base(jsonPath))
+	jsonData, err := json.MarshalIndent(Document{Sheets: sheets}, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("marshalling to JSON: %w", err)
+	}
+
+	switch format {
+	case "json":
+		return writeIfChanged(jsonPath, jsonData)
+	case "json.gz":
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(jsonData); err != nil {
+			return false, fmt.Errorf("gzipping JSON: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return false, fmt.Errorf("gzipping JSON: %w", err)
 		}
+		return writeIfChanged(jsonPath, buf.Bytes())
+	default:
+		return false, fmt.Errorf("--format %s requires --split (the nested multi-sheet document is JSON-only)", format)
 	}
 }
 
+func writeSplit(filePath, ext string, sheets []Sheet, format string) error {
+	base := strings.TrimSuffix(filePath, ext)
+	encoder := encoders[format]
 
-import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
-	"strings"
+	for _, sheet := range sheets {
+		outPath := base + "." + sanitizeSheetName(sheet.Name) + formatExt[format]
 
-	"github.com/xuri/excelize/v2"
-)
+		var buf bytes.Buffer
+		if err := encoder.Encode(&buf, sheet.Rows); err != nil {
+			return fmt.Errorf("encoding sheet %q: %w", sheet.Name, err)
+		}
 
-func main() {
-	dir := "src/data"
+		wrote, err := writeIfChanged(outPath, buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("writing sheet %q: %w", sheet.Name, err)
+		}
 
-	files, err := ioutil.ReadDir(dir)
-	if err != nil {
-		fmt.Println("Error reading directory:", err)
-		return
+		if wrote {
+			fmt.Println("Converted sheet", sheet.Name, "to", filepath.Base(outPath))
+		} else {
+			fmt.Println(filepath.Base(outPath), "is already up to date")
+		}
 	}
 
-	for _, file := range files {
-		ext := filepath.Ext(file.Name())
-		if ext == ".xlsx" || ext == ".xls" {
-			filePath := filepath.Join(dir, file.Name())
-			jsonPath := strings.TrimSuffix(filePath, ext) + ".json"
-
-			f, err := excelize.OpenFile(filePath)
-			if err != nil {
-				fmt.Println("Error opening file:", err)
-				continue
-			}
+	return nil
+}
 
-			sheetName := f.GetSheetName(0)
-			rows, err := f.GetRows(sheetName)
-			if err != nil {
-				fmt.Println("Error getting rows:", err)
-				continue
-			}
+// writeIfChanged writes data to path unless the file already holds an
+// equal value, so unchanged workbooks don't produce git diffs or trigger
+// downstream rebuilds on every run. It reports whether it wrote.
+func writeIfChanged(path string, data []byte) (bool, error) {
+	if matchesCurrent(path, data) {
+		return false, nil
+	}
 
-			if len(rows) == 0 {
-				fmt.Println("No rows found in", file.Name())
-				continue
-			}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return false, err
+	}
 
-			headers := rows[0]
-			data := make([]map[string]interface{}, 0)
+	return true, nil
+}
 
-			for _, row := range rows[1:] {
-				rowData := make(map[string]interface{})
-				for i, cell := range row {
-					if i < len(headers) {
-						rowData[headers[i]] = cell
-					}
-				}
-				data = append(data, rowData)
-			}
+// matchesCurrent reports whether path already holds content equal to
+// data. JSON payloads are compared by value so key reordering doesn't
+// cause spurious rewrites; other formats (ndjson, csv, gzip) fall back
+// to a byte-for-byte comparison.
+func matchesCurrent(path string, data []byte) bool {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
 
-			jsonData, err := json.MarshalIndent(data, "", "  ")
-			if err != nil {
-				fmt.Println("Error marshalling to JSON:", err)
-				continue
-			}
+	var existingValue, newValue interface{}
+	errExisting := json.Unmarshal(existing, &existingValue)
+	errNew := json.Unmarshal(data, &newValue)
+	if errExisting == nil && errNew == nil {
+		return reflect.DeepEqual(existingValue, newValue)
+	}
 
-			err = ioutil.WriteFile(jsonPath, jsonData, 0644)
-			if err != nil {
-				fmt.Println("Error writing JSON file:", err)
-				continue
-			}
+	return bytes.Equal(existing, data)
+}
 
-			fmt.Println("Converted", file.Name(), "to", filepath.Base(jsonPath))
-		}
-	}
+// sanitizeSheetName makes a sheet name safe to use as a filename fragment.
+func sanitizeSheetName(name string) string {
+	replacer := strings.NewReplacer("/", "-", "\\", "-", " ", "_")
+	return replacer.Replace(name)
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// writeFixture creates a single-sheet workbook with the given headers and
+// rows at path, for use as test input.
+func writeFixture(t *testing.T, path string, headers []string, rows [][]interface{}) {
+	t.Helper()
+
+	f := excelize.NewFile()
+	headerRow := make([]interface{}, len(headers))
+	for i, h := range headers {
+		headerRow[i] = h
+	}
+	if err := f.SetSheetRow("Sheet1", "A1", &headerRow); err != nil {
+		t.Fatalf("SetSheetRow headers: %v", err)
+	}
+	for i, row := range rows {
+		axis, _ := excelize.CoordinatesToCellName(1, i+2)
+		rowCopy := row
+		if err := f.SetSheetRow("Sheet1", axis, &rowCopy); err != nil {
+			t.Fatalf("SetSheetRow row %d: %v", i, err)
+		}
+	}
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("SaveAs: %v", err)
+	}
+}
+
+func TestConverterRunProcessesAllFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	for i := 0; i < 5; i++ {
+		writeFixture(t, filepath.Join(dir, "book"+string(rune('A'+i))+".xlsx"),
+			[]string{"name", "count"},
+			[][]interface{}{{"a", i}, {"b", i * 2}})
+	}
+
+	c := NewConverter(dir, false, "json")
+	if err := c.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		jsonPath := filepath.Join(dir, "book"+string(rune('A'+i))+".json")
+		data, err := os.ReadFile(jsonPath)
+		if err != nil {
+			t.Fatalf("reading %s: %v", jsonPath, err)
+		}
+
+		var doc Document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("unmarshalling %s: %v", jsonPath, err)
+		}
+		if len(doc.Sheets) != 1 || len(doc.Sheets[0].Rows) != 2 {
+			t.Errorf("%s: got %+v, want one sheet with two rows", jsonPath, doc)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ColumnType is the inferred or declared type of a column's values.
+type ColumnType string
+
+const (
+	TypeString ColumnType = "string"
+	TypeInt    ColumnType = "int"
+	TypeFloat  ColumnType = "float"
+	TypeBool   ColumnType = "bool"
+	TypeDate   ColumnType = "date"
+)
+
+// ColumnSchema overrides inference for named columns, loaded from a
+// sidecar schema.yaml next to the workbook.
+type ColumnSchema map[string]ColumnType
+
+// sampleSize is how many non-empty cells of a column are inspected when
+// inferring its type.
+const sampleSize = 20
+
+// loadSchema reads "<workbook>.schema.yaml" next to filePath, if present.
+// It returns an empty (non-nil) schema when there is no sidecar file.
+func loadSchema(filePath, ext string) (ColumnSchema, error) {
+	schemaPath := strings.TrimSuffix(filePath, ext) + ".schema.yaml"
+
+	raw, err := os.ReadFile(schemaPath)
+	if os.IsNotExist(err) {
+		return ColumnSchema{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", schemaPath, err)
+	}
+
+	var declared map[string]string
+	if err := yaml.Unmarshal(raw, &declared); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", schemaPath, err)
+	}
+
+	schema := make(ColumnSchema, len(declared))
+	for column, typeName := range declared {
+		schema[column] = ColumnType(typeName)
+	}
+
+	return schema, nil
+}
+
+// inferColumnTypes chooses a ColumnType per header, sampling up to
+// sampleSize non-empty cells and falling back to the schema override when
+// one is declared for that column.
+func inferColumnTypes(f *excelize.File, sheet string, headers []string, dataRows [][]string, schema ColumnSchema) map[string]ColumnType {
+	types := make(map[string]ColumnType, len(headers))
+
+	for col, header := range headers {
+		if declared, ok := schema[header]; ok {
+			types[header] = declared
+			continue
+		}
+
+		var samples []string
+		dateLike := false
+
+		for rowIdx, row := range dataRows {
+			if col >= len(row) || row[col] == "" {
+				continue
+			}
+
+			if isDateFormatted(f, sheet, rowIdx, col) {
+				dateLike = true
+			}
+
+			samples = append(samples, row[col])
+			if len(samples) >= sampleSize {
+				break
+			}
+		}
+
+		types[header] = classify(samples, dateLike)
+	}
+
+	return types
+}
+
+func classify(samples []string, dateLike bool) ColumnType {
+	if len(samples) == 0 {
+		return TypeString
+	}
+
+	if dateLike {
+		return TypeDate
+	}
+
+	allBool, allInt, allFloat := true, true, true
+	for _, s := range samples {
+		if _, err := strconv.ParseBool(s); err != nil {
+			allBool = false
+		}
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			allInt = false
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			allFloat = false
+		}
+	}
+
+	switch {
+	case allInt:
+		return TypeInt
+	case allFloat:
+		return TypeFloat
+	case allBool:
+		return TypeBool
+	default:
+		return TypeString
+	}
+}
+
+// coerceCell converts a raw cell string to the Go value its column type
+// implies. rowIdx/col are 0-based relative to the first data row and
+// first column, used to resolve the cell's coordinates for date-serial
+// conversion.
+func coerceCell(f *excelize.File, sheet string, colType ColumnType, raw string, rowIdx, col int) interface{} {
+	switch colType {
+	case TypeBool:
+		if v, err := strconv.ParseBool(raw); err == nil {
+			return v
+		}
+	case TypeInt:
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v
+		}
+	case TypeFloat:
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+	case TypeDate:
+		if t, ok := parseCellDate(f, sheet, raw, rowIdx, col); ok {
+			return t.Format(time.RFC3339)
+		}
+	}
+
+	return raw
+}
+
+// parseCellDate handles both Excel serial dates (e.g. 45000, read via the
+// cell's raw unformatted value so excelize's own display formatting
+// doesn't get in the way) and dates that are stored as literal text.
+func parseCellDate(f *excelize.File, sheet, raw string, rowIdx, col int) (time.Time, bool) {
+	is1904 := false
+	if props, err := f.GetWorkbookProps(); err == nil && props.Date1904 != nil {
+		is1904 = *props.Date1904
+	}
+
+	if axis, err := excelize.CoordinatesToCellName(col+1, rowIdx+2); err == nil {
+		if rawValue, err := f.GetCellValue(sheet, axis, excelize.Options{RawCellValue: true}); err == nil {
+			if serial, err := strconv.ParseFloat(rawValue, 64); err == nil {
+				if t, err := excelize.ExcelDateToTime(serial, is1904); err == nil {
+					return t, true
+				}
+			}
+		}
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02", "2006-01-02 15:04:05", "01/02/2006"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+var dateNumFmtTokens = regexp.MustCompile(`(?i)yy|dd|mmm|h:mm`)
+
+// builtinDateNumFmts are the well-known Excel number-format IDs for dates
+// and times (ECMA-376 §18.8.30).
+var builtinDateNumFmts = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true,
+	21: true, 22: true, 27: true, 28: true, 29: true, 30: true, 31: true,
+	32: true, 33: true, 34: true, 35: true, 36: true, 45: true, 46: true,
+	47: true,
+}
+
+// isDateFormatted reports whether the cell at (rowIdx, col) — both 0-based
+// relative to the first data row and first column — carries a date/time
+// number format, so a raw numeric value should be read as an Excel serial
+// date rather than a plain number.
+func isDateFormatted(f *excelize.File, sheet string, rowIdx, col int) bool {
+	axis, err := excelize.CoordinatesToCellName(col+1, rowIdx+2)
+	if err != nil {
+		return false
+	}
+
+	styleID, err := f.GetCellStyle(sheet, axis)
+	if err != nil {
+		return false
+	}
+
+	style, err := f.GetStyle(styleID)
+	if err != nil || style == nil {
+		return false
+	}
+
+	if style.CustomNumFmt != nil && dateNumFmtTokens.MatchString(*style.CustomNumFmt) {
+		return true
+	}
+
+	return builtinDateNumFmts[style.NumFmt]
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []string
+		dateLike bool
+		want     ColumnType
+	}{
+		{"empty", nil, false, TypeString},
+		{"ints", []string{"1", "2", "3"}, false, TypeInt},
+		{"floats", []string{"1.5", "2", "3.25"}, false, TypeFloat},
+		{"bools", []string{"true", "false"}, false, TypeBool},
+		{"0/1 is an int column, not bool", []string{"0", "1", "0"}, false, TypeInt},
+		{"mixed falls back to string", []string{"1", "yes", "3.5"}, false, TypeString},
+		{"date flag wins over numeric-looking samples", []string{"45000"}, true, TypeDate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classify(tt.samples, tt.dateLike)
+			if got != tt.want {
+				t.Errorf("classify(%v, %v) = %v, want %v", tt.samples, tt.dateLike, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoerceCell(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetCellValue("Sheet1", "A1", 42)
+	f.SetCellValue("Sheet1", "A2", 3.14)
+	f.SetCellValue("Sheet1", "A3", true)
+
+	tests := []struct {
+		name    string
+		colType ColumnType
+		raw     string
+		want    interface{}
+	}{
+		{"int", TypeInt, "42", int64(42)},
+		{"float", TypeFloat, "3.14", 3.14},
+		{"bool", TypeBool, "true", true},
+		{"unparsable int falls back to raw string", TypeInt, "nope", "nope"},
+		{"string passthrough", TypeString, "hello", "hello"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := coerceCell(f, "Sheet1", tt.colType, tt.raw, 0, 0)
+			if got != tt.want {
+				t.Errorf("coerceCell(%v, %q) = %#v, want %#v", tt.colType, tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCellDateSerial(t *testing.T) {
+	f := excelize.NewFile()
+	style, err := f.NewStyle(&excelize.Style{NumFmt: 14})
+	if err != nil {
+		t.Fatalf("NewStyle: %v", err)
+	}
+	if err := f.SetCellValue("Sheet1", "A2", 45000); err != nil {
+		t.Fatalf("SetCellValue: %v", err)
+	}
+	if err := f.SetCellStyle("Sheet1", "A2", "A2", style); err != nil {
+		t.Fatalf("SetCellStyle: %v", err)
+	}
+
+	if !isDateFormatted(f, "Sheet1", 0, 0) {
+		t.Fatal("isDateFormatted = false, want true for a numFmt 14 cell")
+	}
+
+	got, ok := parseCellDate(f, "Sheet1", "45000", 0, 0)
+	if !ok {
+		t.Fatal("parseCellDate returned ok = false")
+	}
+
+	want := time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("parseCellDate = %v, want %v", got, want)
+	}
+}
+
+func TestParseCellDateLiteralText(t *testing.T) {
+	f := excelize.NewFile()
+
+	got, ok := parseCellDate(f, "Sheet1", "2023-06-01", 0, 0)
+	if !ok {
+		t.Fatal("parseCellDate returned ok = false for a literal date string")
+	}
+
+	want := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	if !got.Equal(want) {
+		t.Errorf("parseCellDate = %v, want %v", got, want)
+	}
+}
+
+func TestLoadSchemaOverride(t *testing.T) {
+	dir := t.TempDir()
+	xlsxPath := dir + "/data.xlsx"
+
+	if err := os.WriteFile(dir+"/data.schema.yaml", []byte("killed_count: float\nverified: string\n"), 0644); err != nil {
+		t.Fatalf("writing schema.yaml: %v", err)
+	}
+
+	schema, err := loadSchema(xlsxPath, ".xlsx")
+	if err != nil {
+		t.Fatalf("loadSchema: %v", err)
+	}
+
+	if schema["killed_count"] != TypeFloat {
+		t.Errorf("killed_count = %v, want %v", schema["killed_count"], TypeFloat)
+	}
+	if schema["verified"] != TypeString {
+		t.Errorf("verified = %v, want %v", schema["verified"], TypeString)
+	}
+}
+
+func TestLoadSchemaMissingSidecar(t *testing.T) {
+	dir := t.TempDir()
+	schema, err := loadSchema(dir+"/data.xlsx", ".xlsx")
+	if err != nil {
+		t.Fatalf("loadSchema: %v", err)
+	}
+	if len(schema) != 0 {
+		t.Errorf("schema = %v, want empty", schema)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is how long Watch waits after the first write event for a
+// file before enqueueing its regeneration job, so a burst of writes during
+// an upload produces one conversion instead of many.
+const watchDebounce = 300 * time.Millisecond
+
+// Watch runs c.Run once, then watches c.Dir and reconverts individual
+// workbooks as they change, using a bounded worker pool for the
+// regeneration jobs.
+func (c *Converter) Watch() error {
+	if err := c.Run(); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(c.Dir); err != nil {
+		return err
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	workers := c.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filePath := range jobs {
+				c.convertFile(filePath)
+			}
+		}()
+	}
+	defer func() {
+		close(jobs)
+		wg.Wait()
+	}()
+
+	var mu sync.Mutex
+	pending := make(map[string]bool)
+
+	fmt.Println("Watching", c.Dir, "for changes...")
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			ext := filepath.Ext(event.Name)
+			if ext != ".xlsx" && ext != ".xls" {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			mu.Lock()
+			if pending[event.Name] {
+				mu.Unlock()
+				continue
+			}
+			pending[event.Name] = true
+			mu.Unlock()
+
+			filePath := event.Name
+			go func() {
+				time.Sleep(watchDebounce)
+				mu.Lock()
+				delete(pending, filePath)
+				mu.Unlock()
+				jobs <- filePath
+			}()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Println("Watcher error:", err)
+		}
+	}
+}
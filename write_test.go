@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestMatchesCurrent(t *testing.T) {
+	dir := t.TempDir()
+
+	gzBytes := func(s string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write([]byte(s))
+		gz.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		existing []byte
+		new      []byte
+		want     bool
+	}{
+		{"identical JSON", []byte(`{"a":1,"b":2}`), []byte(`{"a":1,"b":2}`), true},
+		{"JSON with different key order is still equal", []byte(`{"a":1,"b":2}`), []byte(`{"b":2,"a":1}`), true},
+		{"JSON with different values", []byte(`{"a":1}`), []byte(`{"a":2}`), false},
+		{"identical gzip bytes", gzBytes("x"), gzBytes("x"), true},
+		{"different gzip bytes", gzBytes("x"), gzBytes("y"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".out")
+			if err := os.WriteFile(path, tt.existing, 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			if got := matchesCurrent(path, tt.new); got != tt.want {
+				t.Errorf("matchesCurrent = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesCurrentMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	if matchesCurrent(filepath.Join(dir, "missing.json"), []byte(`{}`)) {
+		t.Error("matchesCurrent = true for a file that doesn't exist, want false")
+	}
+}
+
+func TestWriteIfChangedSkipsIdenticalWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+
+	wrote, err := writeIfChanged(path, []byte(`{"a":1}`))
+	if err != nil || !wrote {
+		t.Fatalf("first write: wrote=%v err=%v, want wrote=true", wrote, err)
+	}
+
+	wrote, err = writeIfChanged(path, []byte(`{"a":1}`))
+	if err != nil || wrote {
+		t.Fatalf("second write: wrote=%v err=%v, want wrote=false", wrote, err)
+	}
+}
+
+// TestConvertFileIsIdempotentAcrossRuns is a regression test for the
+// chunk0-1 sheet-ordering bug: with GetSheetMap's randomized iteration
+// order, the nested Document JSON for a multi-sheet workbook compared
+// unequal from run to run even though nothing changed, so this would
+// flap between "Converted" and "already up to date". Repeated
+// conversions of an unchanged multi-sheet workbook must only write once.
+func TestConvertFileIsIdempotentAcrossRuns(t *testing.T) {
+	dir := t.TempDir()
+	xlsxPath := filepath.Join(dir, "book.xlsx")
+
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", "Casualties")
+	f.SetCellValue("Casualties", "A1", "name")
+	f.SetCellValue("Casualties", "A2", "Gaza")
+	idx, _ := f.NewSheet("ByRegion")
+	f.SetCellValue("ByRegion", "A1", "region")
+	f.SetCellValue("ByRegion", "A2", "North")
+	f.SetActiveSheet(idx)
+	if err := f.SaveAs(xlsxPath); err != nil {
+		t.Fatalf("SaveAs: %v", err)
+	}
+
+	c := NewConverter(dir, false, "json")
+
+	c.convertFile(xlsxPath)
+	jsonPath := filepath.Join(dir, "book.json")
+	first, err := os.Stat(jsonPath)
+	if err != nil {
+		t.Fatalf("stat after first conversion: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		c.convertFile(xlsxPath)
+	}
+
+	second, err := os.Stat(jsonPath)
+	if err != nil {
+		t.Fatalf("stat after repeated conversions: %v", err)
+	}
+	if !first.ModTime().Equal(second.ModTime()) {
+		t.Errorf("book.json was rewritten on an unchanged workbook: mtime %v -> %v", first.ModTime(), second.ModTime())
+	}
+}